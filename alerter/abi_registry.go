@@ -0,0 +1,136 @@
+package alerter
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ABIRegistry binds contract addresses to the ABI used to decode calls made
+// to them, so that WHERE clauses can resolve op.call.arg.<name> attributes
+// against typed, ABI-decoded calldata instead of raw bytes.
+type ABIRegistry struct {
+	mu   sync.RWMutex
+	abis map[string]abi.ABI // keyed by lowercase hex address, without 0x prefix
+}
+
+// NewABIRegistry creates an empty ABIRegistry.
+func NewABIRegistry() *ABIRegistry {
+	return &ABIRegistry{abis: map[string]abi.ABI{}}
+}
+
+func addressKey(address *big.Int) string {
+	return strings.ToLower(address.Text(16))
+}
+
+// Register associates contractABI with address, the same address that is
+// parsed into SelectStmt.From.Address.
+func (r *ABIRegistry) Register(address *big.Int, contractABI abi.ABI) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.abis[addressKey(address)] = contractABI
+}
+
+// Lookup returns the ABI registered for address, if any.
+func (r *ABIRegistry) Lookup(address *big.Int) (abi.ABI, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	contractABI, ok := r.abis[addressKey(address)]
+	return contractABI, ok
+}
+
+// ResolveCallAttribute resolves an "op.call.method", "op.call.selector" or
+// "op.call.arg.<name>[.<field>...]" attribute path by decoding input (the
+// transaction calldata sent to address) against the registered ABI. Nested
+// struct/tuple arguments are traversed field by field, e.g.
+// "op.call.arg.order.maker".
+func (r *ABIRegistry) ResolveCallAttribute(address *big.Int, input []byte, path []string) (Expression, error) {
+	if len(path) < 3 || path[0] != "op" || path[1] != "call" {
+		return nil, fmt.Errorf("not an op.call attribute: %s", strings.Join(path, "."))
+	}
+	if len(input) < 4 {
+		return nil, fmt.Errorf("calldata too short to contain a method selector")
+	}
+	selector := input[:4]
+
+	contractABI, ok := r.Lookup(address)
+	if !ok {
+		return nil, fmt.Errorf("no ABI registered for address 0x%s", addressKey(address))
+	}
+	method, err := contractABI.MethodById(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	switch path[2] {
+	case "selector":
+		return NewStringValue("0x" + hex.EncodeToString(selector)), nil
+	case "method":
+		return NewStringValue(method.Name), nil
+	case "arg":
+		if len(path) < 4 {
+			return nil, fmt.Errorf("op.call.arg requires an argument name")
+		}
+		values, err := method.Inputs.Unpack(input[4:])
+		if err != nil {
+			return nil, fmt.Errorf("decoding arguments for %s: %w", method.Name, err)
+		}
+		return resolveCallArg(method.Inputs, values, path[3:])
+	default:
+		return nil, fmt.Errorf("unknown op.call attribute: %s", path[2])
+	}
+}
+
+func resolveCallArg(inputs abi.Arguments, values []interface{}, path []string) (Expression, error) {
+	for i, arg := range inputs {
+		if arg.Name == path[0] {
+			return abiValueToExpression(values[i], path[1:])
+		}
+	}
+	return nil, fmt.Errorf("unknown call argument: %s", path[0])
+}
+
+// abiValueToExpression converts a value decoded by go-ethereum's abi package
+// into an alerter Expression, descending into struct/tuple fields named by
+// the remainder of path.
+func abiValueToExpression(value interface{}, path []string) (Expression, error) {
+	if len(path) > 0 {
+		rv := reflect.ValueOf(value)
+		for rv.Kind() == reflect.Ptr {
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("cannot resolve field %q on non-tuple value", path[0])
+		}
+		field := rv.FieldByNameFunc(func(name string) bool {
+			return strings.EqualFold(name, path[0])
+		})
+		if !field.IsValid() {
+			return nil, fmt.Errorf("unknown tuple field: %s", path[0])
+		}
+		return abiValueToExpression(field.Interface(), path[1:])
+	}
+
+	switch v := value.(type) {
+	case *big.Int:
+		return NewIntValue(v), nil
+	case bool:
+		return NewBoolValue(v), nil
+	case string:
+		return NewStringValue(v), nil
+	case common.Address:
+		return NewAddressValue(v.Hex()), nil
+	case [32]byte:
+		return NewHashValue("0x" + hex.EncodeToString(v[:])), nil
+	case []byte:
+		return NewStringValue("0x" + hex.EncodeToString(v)), nil
+	default:
+		return nil, fmt.Errorf("unsupported ABI value type %T", value)
+	}
+}
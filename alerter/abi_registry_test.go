@@ -0,0 +1,89 @@
+package alerter
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const fillOrderABI = `[{
+	"type": "function",
+	"name": "fillOrder",
+	"inputs": [
+		{
+			"name": "order",
+			"type": "tuple",
+			"components": [
+				{"name": "maker", "type": "address"},
+				{"name": "amount", "type": "uint256"}
+			]
+		}
+	]
+}]`
+
+func TestABIRegistryResolvesCallAttributes(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(fillOrderABI))
+	assert.Nil(t, err)
+
+	maker := common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	type order struct {
+		Maker  common.Address
+		Amount *big.Int
+	}
+	calldata, err := contractABI.Pack("fillOrder", order{Maker: maker, Amount: big.NewInt(42)})
+	assert.Nil(t, err)
+
+	address := big.NewInt(0x1234abcd)
+	registry := NewABIRegistry()
+	registry.Register(address, contractABI)
+
+	method, err := registry.ResolveCallAttribute(address, calldata, []string{"op", "call", "method"})
+	assert.Nil(t, err)
+	assert.True(t, NewStringValue("fillOrder").Equals(method))
+
+	makerExpr, err := registry.ResolveCallAttribute(address, calldata, []string{"op", "call", "arg", "order", "maker"})
+	assert.Nil(t, err)
+	assert.True(t, NewAddressValue(maker.Hex()).Equals(makerExpr))
+
+	amountExpr, err := registry.ResolveCallAttribute(address, calldata, []string{"op", "call", "arg", "order", "amount"})
+	assert.Nil(t, err)
+	assert.True(t, NewIntValue(big.NewInt(42)).Equals(amountExpr))
+}
+
+func TestABIRegistryAddressArgumentMatchesLiteral(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(fillOrderABI))
+	assert.Nil(t, err)
+
+	maker := common.HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	type order struct {
+		Maker  common.Address
+		Amount *big.Int
+	}
+	calldata, err := contractABI.Pack("fillOrder", order{Maker: maker, Amount: big.NewInt(1)})
+	assert.Nil(t, err)
+
+	address := big.NewInt(0x1234abcd)
+	registry := NewABIRegistry()
+	registry.Register(address, contractABI)
+
+	// Lower-case literal: equality must hold without depending on the
+	// literal's EIP-55 casing, since ABI-decoded addresses are normalized too.
+	query := "select 1 from 0x1234abcd where op.call.arg.order.maker = 0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	parser, err := NewParser(NewLexer(query))
+	assert.Nil(t, err)
+	stmt, err := parser.ParseSelect()
+	assert.Nil(t, err)
+
+	ctx := NewEvalContext()
+	ctx.ABIs = registry
+	ctx.ContractAddress = address
+	ctx.CallData = calldata
+
+	result, err := Evaluate(stmt.Where, ctx)
+	assert.Nil(t, err)
+	assert.True(t, NewBoolValue(true).Equals(result))
+}
@@ -0,0 +1,379 @@
+package alerter
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Expression is implemented by every node of the alerter query AST.
+type Expression interface {
+	// Equals reports whether other is structurally identical to this node.
+	Equals(other Expression) bool
+	String() string
+}
+
+// IntValue is an integer literal.
+type IntValue struct {
+	Value *big.Int
+}
+
+// NewIntValue wraps value in an IntValue expression.
+func NewIntValue(value *big.Int) *IntValue {
+	return &IntValue{Value: value}
+}
+
+func (v *IntValue) Equals(other Expression) bool {
+	o, ok := other.(*IntValue)
+	return ok && v.Value.Cmp(o.Value) == 0
+}
+
+func (v *IntValue) String() string {
+	return v.Value.String()
+}
+
+// StringValue is a string literal.
+type StringValue struct {
+	Value string
+}
+
+// NewStringValue wraps value in a StringValue expression.
+func NewStringValue(value string) *StringValue {
+	return &StringValue{Value: value}
+}
+
+func (v *StringValue) Equals(other Expression) bool {
+	o, ok := other.(*StringValue)
+	return ok && v.Value == o.Value
+}
+
+func (v *StringValue) String() string {
+	return fmt.Sprintf("%q", v.Value)
+}
+
+// BoolValue is a boolean literal (true/false).
+type BoolValue struct {
+	Value bool
+}
+
+// NewBoolValue wraps value in a BoolValue expression.
+func NewBoolValue(value bool) *BoolValue {
+	return &BoolValue{Value: value}
+}
+
+func (v *BoolValue) Equals(other Expression) bool {
+	o, ok := other.(*BoolValue)
+	return ok && v.Value == o.Value
+}
+
+func (v *BoolValue) String() string {
+	if v.Value {
+		return "true"
+	}
+	return "false"
+}
+
+// AddressValue is a 20-byte Ethereum address literal, e.g. 0xAbC...; the
+// lexer stores it lowercased, having already verified any mixed-case input
+// against its EIP-55 checksum.
+type AddressValue struct {
+	Value string
+}
+
+// NewAddressValue wraps value (a "0x"-prefixed 40-hex-char address) in an AddressValue.
+func NewAddressValue(value string) *AddressValue {
+	return &AddressValue{Value: strings.ToLower(value)}
+}
+
+func (a *AddressValue) Equals(other Expression) bool {
+	o, ok := other.(*AddressValue)
+	return ok && a.Value == o.Value
+}
+
+func (a *AddressValue) String() string {
+	return a.Value
+}
+
+// HashValue is a 32-byte literal, e.g. a transaction or block hash.
+type HashValue struct {
+	Value string
+}
+
+// NewHashValue wraps value (a "0x"-prefixed 64-hex-char hash) in a HashValue.
+func NewHashValue(value string) *HashValue {
+	return &HashValue{Value: strings.ToLower(value)}
+}
+
+func (h *HashValue) Equals(other Expression) bool {
+	o, ok := other.(*HashValue)
+	return ok && h.Value == o.Value
+}
+
+func (h *HashValue) String() string {
+	return h.Value
+}
+
+// Attribute references a dotted path such as msg.value or op.call.arg.value,
+// resolved against the evaluation context at evaluation time.
+type Attribute struct {
+	Path []string
+}
+
+// NewAttribute wraps path in an Attribute expression.
+func NewAttribute(path []string) *Attribute {
+	return &Attribute{Path: path}
+}
+
+func (a *Attribute) Equals(other Expression) bool {
+	o, ok := other.(*Attribute)
+	if !ok || len(a.Path) != len(o.Path) {
+		return false
+	}
+	for i := range a.Path {
+		if a.Path[i] != o.Path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *Attribute) String() string {
+	return strings.Join(a.Path, ".")
+}
+
+// FunctionCall is a call such as SUM(msg.value) or COUNT(tx). Name is always
+// stored lowercased so that callers don't need to normalize case themselves.
+// Options carries the trailing `{key: value, ...}` literal some aggregates
+// accept, e.g. SUM(msg.value, {overflow: "saturate"}); it is nil when absent.
+type FunctionCall struct {
+	Name    string
+	Args    []Expression
+	Options map[string]Expression
+}
+
+// NewFunctionCall builds a FunctionCall with no options, lowercasing name.
+func NewFunctionCall(name string, args []Expression) *FunctionCall {
+	return NewFunctionCallWithOptions(name, args, nil)
+}
+
+// NewFunctionCallWithOptions builds a FunctionCall carrying an options
+// literal, lowercasing name.
+func NewFunctionCallWithOptions(name string, args []Expression, options map[string]Expression) *FunctionCall {
+	return &FunctionCall{Name: strings.ToLower(name), Args: args, Options: options}
+}
+
+func (f *FunctionCall) Equals(other Expression) bool {
+	o, ok := other.(*FunctionCall)
+	if !ok || f.Name != o.Name || len(f.Args) != len(o.Args) || len(f.Options) != len(o.Options) {
+		return false
+	}
+	for i := range f.Args {
+		if !f.Args[i].Equals(o.Args[i]) {
+			return false
+		}
+	}
+	for key, value := range f.Options {
+		otherValue, ok := o.Options[key]
+		if !ok || !value.Equals(otherValue) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *FunctionCall) String() string {
+	args := make([]string, len(f.Args))
+	for i, arg := range f.Args {
+		args[i] = arg.String()
+	}
+	if len(f.Options) > 0 {
+		opts := make([]string, 0, len(f.Options))
+		for key, value := range f.Options {
+			opts = append(opts, fmt.Sprintf("%s: %s", key, value.String()))
+		}
+		args = append(args, fmt.Sprintf("{%s}", strings.Join(opts, ", ")))
+	}
+	return fmt.Sprintf("%s(%s)", f.Name, strings.Join(args, ", "))
+}
+
+// CastApplication lowers a `CAST(expr AS type)` expression, forcing operand
+// to be interpreted as targetType regardless of its inferred type.
+type CastApplication struct {
+	Operand    Expression
+	TargetType ValueType
+}
+
+// NewCastApplication builds a CastApplication.
+func NewCastApplication(operand Expression, targetType ValueType) *CastApplication {
+	return &CastApplication{Operand: operand, TargetType: targetType}
+}
+
+func (c *CastApplication) Equals(other Expression) bool {
+	o, ok := other.(*CastApplication)
+	return ok && c.TargetType == o.TargetType && c.Operand.Equals(o.Operand)
+}
+
+func (c *CastApplication) String() string {
+	return fmt.Sprintf("CAST(%s AS %s)", c.Operand.String(), c.TargetType.String())
+}
+
+var validUnaryOperators = map[string]bool{"+": true, "-": true}
+
+// UnaryApplication applies a prefix operator (+/-) to a single operand.
+type UnaryApplication struct {
+	Operand  Expression
+	Operator string
+}
+
+// NewUnaryApplication builds a UnaryApplication, rejecting unknown operators.
+func NewUnaryApplication(operand Expression, operator string) (*UnaryApplication, error) {
+	if !validUnaryOperators[operator] {
+		return nil, fmt.Errorf("invalid unary operator: %s", operator)
+	}
+	return &UnaryApplication{Operand: operand, Operator: operator}, nil
+}
+
+// MustNewUnaryApplication is like NewUnaryApplication but panics on error. It
+// is intended for constructing expressions from trusted, constant operators.
+func MustNewUnaryApplication(operand Expression, operator string) *UnaryApplication {
+	u, err := NewUnaryApplication(operand, operator)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func (u *UnaryApplication) Equals(other Expression) bool {
+	o, ok := other.(*UnaryApplication)
+	return ok && u.Operator == o.Operator && u.Operand.Equals(o.Operand)
+}
+
+func (u *UnaryApplication) String() string {
+	return fmt.Sprintf("%s%s", u.Operator, u.Operand.String())
+}
+
+var validBinaryOperators = map[string]bool{"+": true, "-": true, "*": true, "/": true}
+
+// BinaryApplication applies an arithmetic operator (+ - * /) to two operands.
+type BinaryApplication struct {
+	Left     Expression
+	Right    Expression
+	Operator string
+}
+
+// NewBinaryApplication builds a BinaryApplication, rejecting unknown operators.
+func NewBinaryApplication(left, right Expression, operator string) (*BinaryApplication, error) {
+	if !validBinaryOperators[operator] {
+		return nil, fmt.Errorf("invalid binary operator: %s", operator)
+	}
+	return &BinaryApplication{Left: left, Right: right, Operator: operator}, nil
+}
+
+// MustNewBinaryApplication is like NewBinaryApplication but panics on error. It
+// is intended for constructing expressions from trusted, constant operators.
+func MustNewBinaryApplication(left, right Expression, operator string) *BinaryApplication {
+	b, err := NewBinaryApplication(left, right, operator)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (b *BinaryApplication) Equals(other Expression) bool {
+	o, ok := other.(*BinaryApplication)
+	return ok && b.Operator == o.Operator && b.Left.Equals(o.Left) && b.Right.Equals(o.Right)
+}
+
+func (b *BinaryApplication) String() string {
+	return fmt.Sprintf("(%s %s %s)", b.Left.String(), b.Operator, b.Right.String())
+}
+
+var validComparisonOperators = map[string]bool{
+	"=": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+}
+
+// ComparisonApplication compares two operands and evaluates to a BoolValue.
+type ComparisonApplication struct {
+	Left     Expression
+	Right    Expression
+	Operator string
+}
+
+// NewComparisonApplication builds a ComparisonApplication, rejecting unknown operators.
+func NewComparisonApplication(left, right Expression, operator string) (*ComparisonApplication, error) {
+	if !validComparisonOperators[operator] {
+		return nil, fmt.Errorf("invalid comparison operator: %s", operator)
+	}
+	return &ComparisonApplication{Left: left, Right: right, Operator: operator}, nil
+}
+
+// MustNewComparisonApplication is like NewComparisonApplication but panics on error.
+func MustNewComparisonApplication(left, right Expression, operator string) *ComparisonApplication {
+	c, err := NewComparisonApplication(left, right, operator)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func (c *ComparisonApplication) Equals(other Expression) bool {
+	o, ok := other.(*ComparisonApplication)
+	return ok && c.Operator == o.Operator && c.Left.Equals(o.Left) && c.Right.Equals(o.Right)
+}
+
+func (c *ComparisonApplication) String() string {
+	return fmt.Sprintf("(%s %s %s)", c.Left.String(), c.Operator, c.Right.String())
+}
+
+// logicalOperatorArity gives the number of operands each logical operator takes.
+var logicalOperatorArity = map[string]int{"and": 2, "or": 2, "not": 1}
+
+// LogicalApplication applies a logical operator (AND, OR, NOT) to its operands
+// and evaluates to a BoolValue. AND and OR are binary, NOT is unary.
+type LogicalApplication struct {
+	Operator string
+	Operands []Expression
+}
+
+// NewLogicalApplication builds a LogicalApplication, rejecting unknown
+// operators and operand counts that don't match the operator's arity.
+func NewLogicalApplication(operator string, operands ...Expression) (*LogicalApplication, error) {
+	op := strings.ToLower(operator)
+	arity, ok := logicalOperatorArity[op]
+	if !ok {
+		return nil, fmt.Errorf("invalid logical operator: %s", operator)
+	}
+	if len(operands) != arity {
+		return nil, fmt.Errorf("logical operator %s expects %d operand(s), got %d", op, arity, len(operands))
+	}
+	return &LogicalApplication{Operator: op, Operands: operands}, nil
+}
+
+// MustNewLogicalApplication is like NewLogicalApplication but panics on error.
+func MustNewLogicalApplication(operator string, operands ...Expression) *LogicalApplication {
+	l, err := NewLogicalApplication(operator, operands...)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+func (l *LogicalApplication) Equals(other Expression) bool {
+	o, ok := other.(*LogicalApplication)
+	if !ok || l.Operator != o.Operator || len(l.Operands) != len(o.Operands) {
+		return false
+	}
+	for i := range l.Operands {
+		if !l.Operands[i].Equals(o.Operands[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *LogicalApplication) String() string {
+	if l.Operator == "not" {
+		return fmt.Sprintf("(NOT %s)", l.Operands[0].String())
+	}
+	return fmt.Sprintf("(%s %s %s)", l.Operands[0].String(), strings.ToUpper(l.Operator), l.Operands[1].String())
+}
@@ -0,0 +1,24 @@
+package alerter
+
+import "math/big"
+
+// denominationMultipliers gives the wei-scaling factor for every numeric
+// literal suffix the lexer accepts, mirroring go-ethereum's params package
+// denominations (wei, gwei, ether, ...) so that query authors can write
+// "1 ether" or "5 gwei" instead of pasting 19-digit wei constants.
+var denominationMultipliers = map[string]*big.Int{
+	"wei":     weiMultiplier(0),
+	"kwei":    weiMultiplier(3),
+	"ada":     weiMultiplier(3),
+	"mwei":    weiMultiplier(6),
+	"babbage": weiMultiplier(6),
+	"gwei":    weiMultiplier(9),
+	"shannon": weiMultiplier(9),
+	"szabo":   weiMultiplier(12),
+	"finney":  weiMultiplier(15),
+	"ether":   weiMultiplier(18),
+}
+
+func weiMultiplier(exp int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil)
+}
@@ -0,0 +1,249 @@
+package alerter
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// EvalContext supplies the attribute values (msg.value, tx.hash, ...) that an
+// Expression is evaluated against, e.g. the fields of one observed transaction.
+// When ABIs is set, "op.call.*" attributes are resolved by decoding CallData
+// against the ABI registered for ContractAddress instead of a plain lookup.
+type EvalContext struct {
+	Attributes      map[string]Expression
+	ABIs            *ABIRegistry
+	ContractAddress *big.Int
+	CallData        []byte
+}
+
+// NewEvalContext creates an empty EvalContext.
+func NewEvalContext() *EvalContext {
+	return &EvalContext{Attributes: map[string]Expression{}}
+}
+
+// Resolve looks up the value bound to the dotted attribute path.
+func (c *EvalContext) Resolve(path []string) (Expression, error) {
+	if c.ABIs != nil && len(path) >= 2 && path[0] == "op" && path[1] == "call" {
+		return c.ABIs.ResolveCallAttribute(c.ContractAddress, c.CallData, path)
+	}
+	value, ok := c.Attributes[strings.Join(path, ".")]
+	if !ok {
+		return nil, fmt.Errorf("unresolved attribute: %s", strings.Join(path, "."))
+	}
+	return value, nil
+}
+
+// Evaluate reduces expr to a literal value (IntValue, StringValue, BoolValue,
+// AddressValue or HashValue) against ctx. Aggregate function calls such as SUM/COUNT are not
+// reducible row-by-row and return an error; they are handled by the
+// aggregation stage instead.
+func Evaluate(expr Expression, ctx *EvalContext) (Expression, error) {
+	switch e := expr.(type) {
+	case *IntValue, *StringValue, *BoolValue, *AddressValue, *HashValue:
+		return e, nil
+	case *Attribute:
+		return ctx.Resolve(e.Path)
+	case *UnaryApplication:
+		return evaluateUnary(e, ctx)
+	case *BinaryApplication:
+		return evaluateBinary(e, ctx)
+	case *ComparisonApplication:
+		return evaluateComparison(e, ctx)
+	case *LogicalApplication:
+		return evaluateLogical(e, ctx)
+	case *CastApplication:
+		return evaluateCast(e, ctx)
+	case *FunctionCall:
+		return nil, fmt.Errorf("%s() is an aggregate function and cannot be evaluated outside aggregation", e.Name)
+	default:
+		return nil, fmt.Errorf("unsupported expression type %T", expr)
+	}
+}
+
+func evaluateUnary(u *UnaryApplication, ctx *EvalContext) (Expression, error) {
+	operand, err := Evaluate(u.Operand, ctx)
+	if err != nil {
+		return nil, err
+	}
+	intOperand, ok := operand.(*IntValue)
+	if !ok {
+		return nil, fmt.Errorf("unary %s requires an integer operand, got %s", u.Operator, operand)
+	}
+	if u.Operator == "-" {
+		return NewIntValue(new(big.Int).Neg(intOperand.Value)), nil
+	}
+	return intOperand, nil
+}
+
+func evaluateBinary(b *BinaryApplication, ctx *EvalContext) (Expression, error) {
+	left, err := Evaluate(b.Left, ctx)
+	if err != nil {
+		return nil, err
+	}
+	right, err := Evaluate(b.Right, ctx)
+	if err != nil {
+		return nil, err
+	}
+	leftInt, ok := left.(*IntValue)
+	if !ok {
+		return nil, fmt.Errorf("%s requires integer operands, got %s", b.Operator, left)
+	}
+	rightInt, ok := right.(*IntValue)
+	if !ok {
+		return nil, fmt.Errorf("%s requires integer operands, got %s", b.Operator, right)
+	}
+
+	result := new(big.Int)
+	switch b.Operator {
+	case "+":
+		result.Add(leftInt.Value, rightInt.Value)
+	case "-":
+		result.Sub(leftInt.Value, rightInt.Value)
+	case "*":
+		result.Mul(leftInt.Value, rightInt.Value)
+	case "/":
+		if rightInt.Value.Sign() == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		result.Div(leftInt.Value, rightInt.Value)
+	default:
+		return nil, fmt.Errorf("unsupported binary operator %q", b.Operator)
+	}
+	return NewIntValue(result), nil
+}
+
+func evaluateComparison(c *ComparisonApplication, ctx *EvalContext) (Expression, error) {
+	left, err := Evaluate(c.Left, ctx)
+	if err != nil {
+		return nil, err
+	}
+	right, err := Evaluate(c.Right, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Operator == "=" || c.Operator == "!=" {
+		equal := left.Equals(right)
+		if c.Operator == "!=" {
+			equal = !equal
+		}
+		return NewBoolValue(equal), nil
+	}
+
+	leftInt, ok := left.(*IntValue)
+	if !ok {
+		return nil, fmt.Errorf("%s requires integer operands, got %s", c.Operator, left)
+	}
+	rightInt, ok := right.(*IntValue)
+	if !ok {
+		return nil, fmt.Errorf("%s requires integer operands, got %s", c.Operator, right)
+	}
+	cmp := leftInt.Value.Cmp(rightInt.Value)
+	var result bool
+	switch c.Operator {
+	case "<":
+		result = cmp < 0
+	case "<=":
+		result = cmp <= 0
+	case ">":
+		result = cmp > 0
+	case ">=":
+		result = cmp >= 0
+	default:
+		return nil, fmt.Errorf("unsupported comparison operator %q", c.Operator)
+	}
+	return NewBoolValue(result), nil
+}
+
+// evaluateCast evaluates c.Operand and coerces the result to c.TargetType.
+// Address, bytes and timestamp targets produce a StringValue holding the
+// operand's string form; as for a string target, only IntValue, StringValue
+// and BoolValue operands are accepted, so an AddressValue or HashValue
+// operand is rejected.
+func evaluateCast(c *CastApplication, ctx *EvalContext) (Expression, error) {
+	value, err := Evaluate(c.Operand, ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch c.TargetType {
+	case TypeInt, TypeUint256:
+		return castToInt(value)
+	case TypeBool:
+		return castToBool(value)
+	case TypeString, TypeAddress, TypeBytes, TypeTimestamp:
+		return castToString(value)
+	default:
+		return nil, fmt.Errorf("cannot cast to %s", c.TargetType)
+	}
+}
+
+func castToInt(value Expression) (Expression, error) {
+	switch v := value.(type) {
+	case *IntValue:
+		return v, nil
+	case *StringValue:
+		parsed, ok := new(big.Int).SetString(v.Value, 0)
+		if !ok {
+			return nil, fmt.Errorf("cannot cast %q to an integer", v.Value)
+		}
+		return NewIntValue(parsed), nil
+	case *BoolValue:
+		if v.Value {
+			return NewIntValue(big.NewInt(1)), nil
+		}
+		return NewIntValue(big.NewInt(0)), nil
+	default:
+		return nil, fmt.Errorf("cannot cast %s to an integer", value)
+	}
+}
+
+func castToBool(value Expression) (Expression, error) {
+	switch v := value.(type) {
+	case *BoolValue:
+		return v, nil
+	case *IntValue:
+		return NewBoolValue(v.Value.Sign() != 0), nil
+	default:
+		return nil, fmt.Errorf("cannot cast %s to a boolean", value)
+	}
+}
+
+func castToString(value Expression) (Expression, error) {
+	switch v := value.(type) {
+	case *StringValue:
+		return v, nil
+	case *IntValue:
+		return NewStringValue(v.Value.String()), nil
+	case *BoolValue:
+		return NewStringValue(v.String()), nil
+	default:
+		return nil, fmt.Errorf("cannot cast %s to a string", value)
+	}
+}
+
+func evaluateLogical(l *LogicalApplication, ctx *EvalContext) (Expression, error) {
+	operands := make([]bool, len(l.Operands))
+	for i, operand := range l.Operands {
+		value, err := Evaluate(operand, ctx)
+		if err != nil {
+			return nil, err
+		}
+		boolValue, ok := value.(*BoolValue)
+		if !ok {
+			return nil, fmt.Errorf("%s requires boolean operands, got %s", l.Operator, value)
+		}
+		operands[i] = boolValue.Value
+	}
+
+	switch l.Operator {
+	case "not":
+		return NewBoolValue(!operands[0]), nil
+	case "and":
+		return NewBoolValue(operands[0] && operands[1]), nil
+	case "or":
+		return NewBoolValue(operands[0] || operands[1]), nil
+	default:
+		return nil, fmt.Errorf("unsupported logical operator %q", l.Operator)
+	}
+}
@@ -0,0 +1,41 @@
+package alerter
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	ctx := NewEvalContext()
+	ctx.Attributes["msg.value"] = NewIntValue(big.NewInt(5))
+
+	testCases := map[string]Expression{
+		"1 + 1":                   NewIntValue(big.NewInt(2)),
+		"msg.value * 2":           NewIntValue(big.NewInt(10)),
+		"msg.value > 1":           NewBoolValue(true),
+		"msg.value > 1 AND false": NewBoolValue(false),
+		"NOT (msg.value > 1)":     NewBoolValue(false),
+		"\"a\" = \"a\"":           NewBoolValue(true),
+	}
+	for input, expected := range testCases {
+		parser, err := NewParser(NewLexer(input))
+		assert.Nil(t, err)
+		exp, err := parser.parseOr()
+		assert.Nil(t, err)
+		result, err := Evaluate(exp, ctx)
+		assert.Nil(t, err)
+		assert.True(t, expected.Equals(result), "%v != %v", expected, result)
+	}
+}
+
+func TestEvaluateUnresolvedAttribute(t *testing.T) {
+	ctx := NewEvalContext()
+	parser, err := NewParser(NewLexer("tx.hash"))
+	assert.Nil(t, err)
+	exp, err := parser.parseOr()
+	assert.Nil(t, err)
+	_, err = Evaluate(exp, ctx)
+	assert.NotNil(t, err)
+}
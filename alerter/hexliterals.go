@@ -0,0 +1,29 @@
+package alerter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	addressHexLen = 2 * common.AddressLength
+	hashHexLen    = 2 * common.HashLength
+)
+
+// normalizeAddressHex validates a 40-character hex string (no "0x" prefix)
+// against EIP-55: if it uses mixed case, that case must be the correct
+// checksum encoding, exactly as common/hexutil expects of address literals
+// elsewhere in go-ethereum. It returns the canonical lowercase digits.
+func normalizeAddressHex(hexDigits string) (string, error) {
+	hasUpper := strings.IndexFunc(hexDigits, func(r rune) bool { return r >= 'A' && r <= 'F' }) >= 0
+	hasLower := strings.IndexFunc(hexDigits, func(r rune) bool { return r >= 'a' && r <= 'f' }) >= 0
+	if hasUpper && hasLower {
+		checksummed := common.HexToAddress(hexDigits).Hex()
+		if checksummed != "0x"+hexDigits {
+			return "", fmt.Errorf("address %q fails EIP-55 checksum", "0x"+hexDigits)
+		}
+	}
+	return strings.ToLower(hexDigits), nil
+}
@@ -0,0 +1,251 @@
+package alerter
+
+import (
+	"math/big"
+	"strings"
+)
+
+// IsValidIdentifier reports whether s is a valid alerter identifier: it must
+// start with a letter or underscore and contain only letters, digits and
+// underscores thereafter.
+func IsValidIdentifier(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_':
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Lexer turns the raw text of an alerter query into a stream of Tokens.
+type Lexer struct {
+	input        string
+	position     int
+	readPosition int
+	ch           byte
+}
+
+// NewLexer creates a Lexer reading from input.
+func NewLexer(input string) *Lexer {
+	l := &Lexer{input: input}
+	l.readChar()
+	return l
+}
+
+func (l *Lexer) readChar() {
+	if l.readPosition >= len(l.input) {
+		l.ch = 0
+	} else {
+		l.ch = l.input[l.readPosition]
+	}
+	l.position = l.readPosition
+	l.readPosition++
+}
+
+func (l *Lexer) peekChar() byte {
+	if l.readPosition >= len(l.input) {
+		return 0
+	}
+	return l.input[l.readPosition]
+}
+
+func (l *Lexer) skipWhitespace() {
+	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+		l.readChar()
+	}
+}
+
+// NextToken consumes and returns the next Token in the input.
+func (l *Lexer) NextToken() Token {
+	l.skipWhitespace()
+
+	switch {
+	case l.ch == 0:
+		return Token{Type: TokenEOF}
+	case l.ch == '+':
+		l.readChar()
+		return Token{Type: TokenPlus, Literal: "+"}
+	case l.ch == '-':
+		l.readChar()
+		return Token{Type: TokenMinus, Literal: "-"}
+	case l.ch == '*':
+		l.readChar()
+		return Token{Type: TokenStar, Literal: "*"}
+	case l.ch == '/':
+		l.readChar()
+		return Token{Type: TokenSlash, Literal: "/"}
+	case l.ch == '(':
+		l.readChar()
+		return Token{Type: TokenLParen, Literal: "("}
+	case l.ch == ')':
+		l.readChar()
+		return Token{Type: TokenRParen, Literal: ")"}
+	case l.ch == ',':
+		l.readChar()
+		return Token{Type: TokenComma, Literal: ","}
+	case l.ch == '{':
+		l.readChar()
+		return Token{Type: TokenLBrace, Literal: "{"}
+	case l.ch == '}':
+		l.readChar()
+		return Token{Type: TokenRBrace, Literal: "}"}
+	case l.ch == ':':
+		l.readChar()
+		return Token{Type: TokenColon, Literal: ":"}
+	case l.ch == '.':
+		l.readChar()
+		return Token{Type: TokenDot, Literal: "."}
+	case l.ch == '=':
+		l.readChar()
+		return Token{Type: TokenEq, Literal: "="}
+	case l.ch == '!' && l.peekChar() == '=':
+		l.readChar()
+		l.readChar()
+		return Token{Type: TokenNotEq, Literal: "!="}
+	case l.ch == '<':
+		l.readChar()
+		if l.ch == '=' {
+			l.readChar()
+			return Token{Type: TokenLte, Literal: "<="}
+		}
+		return Token{Type: TokenLt, Literal: "<"}
+	case l.ch == '>':
+		l.readChar()
+		if l.ch == '=' {
+			l.readChar()
+			return Token{Type: TokenGte, Literal: ">="}
+		}
+		return Token{Type: TokenGt, Literal: ">"}
+	case l.ch == '"':
+		return l.readString()
+	case isDigit(l.ch):
+		return l.readNumber()
+	case isIdentStart(l.ch):
+		return l.readIdentifier()
+	default:
+		ch := l.ch
+		l.readChar()
+		return Token{Type: TokenIllegal, Literal: string(ch)}
+	}
+}
+
+func (l *Lexer) readString() Token {
+	l.readChar() // consume opening quote
+	start := l.position
+	for l.ch != '"' && l.ch != 0 {
+		l.readChar()
+	}
+	literal := l.input[start:l.position]
+	l.readChar() // consume closing quote
+	return Token{Type: TokenString, Literal: literal}
+}
+
+// readNumber reads an integer literal, accepting "0x"-prefixed hex digits or
+// plain decimal digits with optional "_" separators (e.g. 2_000). A hex
+// literal of exactly 40 or 64 digits is returned as an address or hash
+// token instead of an integer. A decimal
+// literal may be followed by whitespace and a denomination suffix (wei, gwei,
+// ether, ...), in which case the literal is scaled accordingly.
+func (l *Lexer) readNumber() Token {
+	var literal strings.Builder
+	if l.ch == '0' && (l.peekChar() == 'x' || l.peekChar() == 'X') {
+		l.readChar()
+		l.readChar()
+		var hexDigits strings.Builder
+		for isHexDigit(l.ch) || l.ch == '_' {
+			if l.ch != '_' {
+				hexDigits.WriteByte(l.ch)
+			}
+			l.readChar()
+		}
+		digits := hexDigits.String()
+
+		switch len(digits) {
+		case addressHexLen:
+			normalized, err := normalizeAddressHex(digits)
+			if err != nil {
+				return Token{Type: TokenIllegal, Literal: err.Error()}
+			}
+			return Token{Type: TokenAddress, Literal: "0x" + normalized}
+		case hashHexLen:
+			return Token{Type: TokenHash, Literal: "0x" + strings.ToLower(digits)}
+		default:
+			return Token{Type: TokenInt, Literal: "0x" + digits}
+		}
+	}
+
+	for isDigit(l.ch) || l.ch == '_' {
+		if l.ch != '_' {
+			literal.WriteByte(l.ch)
+		}
+		l.readChar()
+	}
+
+	if multiplier, ok := l.readDenomination(); ok {
+		value, _ := new(big.Int).SetString(literal.String(), 10)
+		value.Mul(value, multiplier)
+		return Token{Type: TokenInt, Literal: value.String()}
+	}
+	return Token{Type: TokenInt, Literal: literal.String()}
+}
+
+// readDenomination attempts to read a denomination suffix (possibly preceded
+// by whitespace) at the lexer's current position, returning its scaling
+// factor. If no known denomination is found the lexer position is left
+// unchanged so the caller can keep lexing normally.
+func (l *Lexer) readDenomination() (*big.Int, bool) {
+	savedPos, savedReadPos, savedCh := l.position, l.readPosition, l.ch
+
+	for l.ch == ' ' || l.ch == '\t' {
+		l.readChar()
+	}
+	start := l.position
+	for isIdentPart(l.ch) {
+		l.readChar()
+	}
+	word := strings.ToLower(l.input[start:l.position])
+
+	if multiplier, ok := denominationMultipliers[word]; ok {
+		return multiplier, true
+	}
+	l.position, l.readPosition, l.ch = savedPos, savedReadPos, savedCh
+	return nil, false
+}
+
+func (l *Lexer) readIdentifier() Token {
+	start := l.position
+	for isIdentPart(l.ch) {
+		l.readChar()
+	}
+	literal := l.input[start:l.position]
+	if kw, ok := keywords[strings.ToLower(literal)]; ok {
+		return Token{Type: kw, Literal: literal}
+	}
+	return Token{Type: TokenIdent, Literal: literal}
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isHexDigit(ch byte) bool {
+	return isDigit(ch) || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
+
+func isIdentStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isIdentPart(ch byte) bool {
+	return isIdentStart(ch) || isDigit(ch)
+}
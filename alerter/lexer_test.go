@@ -0,0 +1,69 @@
+package alerter
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLexerDenominations(t *testing.T) {
+	testCases := map[string]string{
+		"1 ether":    "1000000000000000000",
+		"1 Ether":    "1000000000000000000",
+		"1 ETHER":    "1000000000000000000",
+		"50 gwei":    "50000000000",
+		"100 finney": "100000000000000000",
+		"2_000 wei":  "2000",
+		"1 kwei":     "1000",
+		"1 ada":      "1000",
+		"1 mwei":     "1000000",
+		"1 babbage":  "1000000",
+		"1 shannon":  "1000000000",
+		"1 szabo":    "1000000000000",
+		"7":          "7",
+	}
+	for input, expected := range testCases {
+		lexer := NewLexer(input)
+		tok := lexer.NextToken()
+		assert.Equal(t, TokenInt, tok.Type)
+		value, ok := new(big.Int).SetString(tok.Literal, 10)
+		assert.True(t, ok)
+		expectedValue, _ := new(big.Int).SetString(expected, 10)
+		assert.Equal(t, 0, expectedValue.Cmp(value), "%s != %s", expected, tok.Literal)
+	}
+}
+
+func TestLexerAddressAndHashLiterals(t *testing.T) {
+	address := "0x52908400098527886E0F7030069857D2E4169EE7"
+	hash := "0x" + strings.Repeat("ab", 32)
+
+	lexer := NewLexer(address)
+	tok := lexer.NextToken()
+	assert.Equal(t, TokenAddress, tok.Type)
+	assert.Equal(t, strings.ToLower(address), tok.Literal)
+
+	lexer = NewLexer(hash)
+	tok = lexer.NextToken()
+	assert.Equal(t, TokenHash, tok.Type)
+	assert.Equal(t, hash, tok.Literal)
+}
+
+func TestLexerRejectsInvalidChecksumAddress(t *testing.T) {
+	// Same digits as a valid checksummed address, but with the case of one
+	// character flipped so the EIP-55 checksum no longer matches.
+	lexer := NewLexer("0x52908400098527886e0F7030069857D2E4169EE7")
+	tok := lexer.NextToken()
+	assert.Equal(t, TokenIllegal, tok.Type)
+}
+
+func TestLexerUnknownSuffixIsNotConsumed(t *testing.T) {
+	lexer := NewLexer("1 apple")
+	tok := lexer.NextToken()
+	assert.Equal(t, TokenInt, tok.Type)
+	assert.Equal(t, "1", tok.Literal)
+	next := lexer.NextToken()
+	assert.Equal(t, TokenIdent, next.Type)
+	assert.Equal(t, "apple", next.Literal)
+}
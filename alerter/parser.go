@@ -0,0 +1,515 @@
+package alerter
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// FromClause identifies the contract address a SELECT statement watches.
+type FromClause struct {
+	Address *big.Int
+}
+
+// SelectStmt is the parsed form of a `SELECT ... FROM <address> [WHERE ...]
+// [GROUP BY ...] [HAVING ...] [WINDOW ...]` query.
+type SelectStmt struct {
+	Selected []Expression
+	Aliases  map[string]Expression
+	From     *FromClause
+	Where    Expression
+	GroupBy  []Expression
+	Having   Expression
+	Window   WindowSpec
+
+	// Types holds the ValueType that InferTypes resolved for every node in
+	// Selected, Where and Having, keyed by the node itself. ParseSelect
+	// populates it by running the inference pass once so later stages (e.g.
+	// the evaluator) can rely on a node's type without re-deriving it.
+	Types map[Expression]ValueType
+}
+
+// Parser builds a SelectStmt (or a standalone Expression) from the tokens
+// produced by a Lexer, using a standard recursive-descent/precedence-climbing
+// scheme. Precedence from loosest to tightest is:
+//
+//	OR > AND > NOT > comparison (= != < <= > >=) > + - > * / > unary +/- > factor
+type Parser struct {
+	lexer *Lexer
+
+	curToken  Token
+	peekToken Token
+}
+
+// NewParser creates a Parser reading tokens from l.
+func NewParser(l *Lexer) (*Parser, error) {
+	p := &Parser{lexer: l}
+	p.nextToken()
+	p.nextToken()
+	return p, nil
+}
+
+func (p *Parser) nextToken() {
+	p.curToken = p.peekToken
+	p.peekToken = p.lexer.NextToken()
+}
+
+func (p *Parser) curTokenIs(t TokenType) bool {
+	return p.curToken.Type == t
+}
+
+func (p *Parser) expect(t TokenType, what string) error {
+	if !p.curTokenIs(t) {
+		return fmt.Errorf("expected %s, got %q", what, p.curToken.Literal)
+	}
+	p.nextToken()
+	return nil
+}
+
+// parseFactor parses the innermost grammar production: literals, attribute
+// paths, function calls and parenthesized expressions.
+func (p *Parser) parseFactor() (Expression, error) {
+	switch p.curToken.Type {
+	case TokenInt:
+		value, ok := new(big.Int).SetString(p.curToken.Literal, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer literal: %q", p.curToken.Literal)
+		}
+		p.nextToken()
+		return NewIntValue(value), nil
+	case TokenString:
+		value := p.curToken.Literal
+		p.nextToken()
+		return NewStringValue(value), nil
+	case TokenAddress:
+		value := p.curToken.Literal
+		p.nextToken()
+		return NewAddressValue(value), nil
+	case TokenHash:
+		value := p.curToken.Literal
+		p.nextToken()
+		return NewHashValue(value), nil
+	case TokenTrue:
+		p.nextToken()
+		return NewBoolValue(true), nil
+	case TokenFalse:
+		p.nextToken()
+		return NewBoolValue(false), nil
+	case TokenIdent:
+		return p.parseIdentOrCall()
+	case TokenCast:
+		return p.parseCast()
+	case TokenLParen:
+		p.nextToken()
+		exp, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(TokenRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return exp, nil
+	default:
+		return nil, fmt.Errorf("unexpected token %q", p.curToken.Literal)
+	}
+}
+
+// parseCast parses a `CAST(expr AS type)` expression.
+func (p *Parser) parseCast() (Expression, error) {
+	p.nextToken() // consume CAST
+	if err := p.expect(TokenLParen, "'('"); err != nil {
+		return nil, err
+	}
+	operand, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(TokenAs, "AS"); err != nil {
+		return nil, err
+	}
+	if !p.curTokenIs(TokenIdent) {
+		return nil, fmt.Errorf("expected type name, got %q", p.curToken.Literal)
+	}
+	targetType, ok := typeNames[strings.ToLower(p.curToken.Literal)]
+	if !ok {
+		return nil, fmt.Errorf("unknown type: %q", p.curToken.Literal)
+	}
+	p.nextToken()
+	if err := p.expect(TokenRParen, "')'"); err != nil {
+		return nil, err
+	}
+	return NewCastApplication(operand, targetType), nil
+}
+
+// parseIdentOrCall parses either a dotted attribute path (msg.value,
+// op.call.arg.value, ...) or a function call (SUM(msg.value, {opt: 1})).
+func (p *Parser) parseIdentOrCall() (Expression, error) {
+	name := p.curToken.Literal
+	p.nextToken()
+
+	if p.curTokenIs(TokenLParen) {
+		p.nextToken()
+		var args []Expression
+		var options map[string]Expression
+		for !p.curTokenIs(TokenRParen) {
+			if p.curTokenIs(TokenLBrace) {
+				opts, err := p.parseOptions()
+				if err != nil {
+					return nil, err
+				}
+				options = opts
+			} else {
+				arg, err := p.parseExpression()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+			}
+			if p.curTokenIs(TokenComma) {
+				p.nextToken()
+			}
+		}
+		if err := p.expect(TokenRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return NewFunctionCallWithOptions(name, args, options), nil
+	}
+
+	path := []string{name}
+	for p.curTokenIs(TokenDot) {
+		p.nextToken()
+		if !p.curTokenIs(TokenIdent) {
+			return nil, fmt.Errorf("expected identifier after '.', got %q", p.curToken.Literal)
+		}
+		path = append(path, p.curToken.Literal)
+		p.nextToken()
+	}
+	return NewAttribute(path), nil
+}
+
+// parseOptions parses a trailing `{key: value, ...}` literal such as
+// {overflow: "saturate"} or {distinct: true}, as accepted by aggregate
+// function calls.
+func (p *Parser) parseOptions() (map[string]Expression, error) {
+	if err := p.expect(TokenLBrace, "'{'"); err != nil {
+		return nil, err
+	}
+	options := map[string]Expression{}
+	for !p.curTokenIs(TokenRBrace) {
+		if !p.curTokenIs(TokenIdent) {
+			return nil, fmt.Errorf("expected option key, got %q", p.curToken.Literal)
+		}
+		key := p.curToken.Literal
+		p.nextToken()
+		if err := p.expect(TokenColon, "':'"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		options[key] = value
+		if p.curTokenIs(TokenComma) {
+			p.nextToken()
+		}
+	}
+	if err := p.expect(TokenRBrace, "'}'"); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// parseUnary parses an optional prefix +/- applied to a factor.
+func (p *Parser) parseUnary() (Expression, error) {
+	if p.curTokenIs(TokenPlus) || p.curTokenIs(TokenMinus) {
+		op := p.curToken.Literal
+		p.nextToken()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NewUnaryApplication(operand, op)
+	}
+	return p.parseFactor()
+}
+
+// parseTerm parses a left-associative chain of * and / operators.
+func (p *Parser) parseTerm() (Expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.curTokenIs(TokenStar) || p.curTokenIs(TokenSlash) {
+		op := p.curToken.Literal
+		p.nextToken()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left, err = NewBinaryApplication(left, right, op)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+// parseExpression parses a left-associative chain of + and - operators.
+func (p *Parser) parseExpression() (Expression, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.curTokenIs(TokenPlus) || p.curTokenIs(TokenMinus) {
+		op := p.curToken.Literal
+		p.nextToken()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left, err = NewBinaryApplication(left, right, op)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+var comparisonTokens = map[TokenType]string{
+	TokenEq:    "=",
+	TokenNotEq: "!=",
+	TokenLt:    "<",
+	TokenLte:   "<=",
+	TokenGt:    ">",
+	TokenGte:   ">=",
+}
+
+// parseComparison parses an optional comparison operator between two
+// arithmetic expressions. Comparisons do not chain.
+func (p *Parser) parseComparison() (Expression, error) {
+	left, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := comparisonTokens[p.curToken.Type]
+	if !ok {
+		return left, nil
+	}
+	p.nextToken()
+	right, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	return NewComparisonApplication(left, right, op)
+}
+
+// parseNot parses an optional NOT prefix, binding tighter than AND/OR.
+func (p *Parser) parseNot() (Expression, error) {
+	if p.curTokenIs(TokenNot) {
+		p.nextToken()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return NewLogicalApplication("not", operand)
+	}
+	return p.parseComparison()
+}
+
+// parseAnd parses a left-associative chain of AND operators.
+func (p *Parser) parseAnd() (Expression, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.curTokenIs(TokenAnd) {
+		p.nextToken()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left, err = NewLogicalApplication("and", left, right)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+// parseOr parses a left-associative chain of OR operators. It is the
+// top-level entry point for any predicate, including WHERE clauses and
+// parenthesized sub-expressions.
+func (p *Parser) parseOr() (Expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.curTokenIs(TokenOr) {
+		p.nextToken()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left, err = NewLogicalApplication("or", left, right)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+// ParseSelect parses a full `SELECT <expr> [AS <alias>] [, ...] FROM <address>
+// [WHERE <predicate>] [GROUP BY <expr> [, ...]] [HAVING <predicate>]
+// [WINDOW <window>]` statement.
+func (p *Parser) ParseSelect() (*SelectStmt, error) {
+	if err := p.expect(TokenSelect, "SELECT"); err != nil {
+		return nil, err
+	}
+
+	stmt := &SelectStmt{Aliases: map[string]Expression{}}
+	for {
+		exp, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Selected = append(stmt.Selected, exp)
+
+		if p.curTokenIs(TokenAs) {
+			p.nextToken()
+			if !p.curTokenIs(TokenIdent) {
+				return nil, fmt.Errorf("expected alias identifier, got %q", p.curToken.Literal)
+			}
+			stmt.Aliases[p.curToken.Literal] = exp
+			p.nextToken()
+		}
+
+		if p.curTokenIs(TokenComma) {
+			p.nextToken()
+			continue
+		}
+		break
+	}
+
+	if err := p.expect(TokenFrom, "FROM"); err != nil {
+		return nil, err
+	}
+	from, err := p.parseFromClause()
+	if err != nil {
+		return nil, err
+	}
+	stmt.From = from
+
+	if p.curTokenIs(TokenWhere) {
+		p.nextToken()
+		where, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Where = where
+	}
+
+	if p.curTokenIs(TokenGroup) {
+		p.nextToken()
+		if err := p.expect(TokenBy, "BY"); err != nil {
+			return nil, err
+		}
+		for {
+			expr, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			stmt.GroupBy = append(stmt.GroupBy, expr)
+			if p.curTokenIs(TokenComma) {
+				p.nextToken()
+				continue
+			}
+			break
+		}
+	}
+
+	if p.curTokenIs(TokenHaving) {
+		p.nextToken()
+		having, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Having = having
+	}
+
+	if p.curTokenIs(TokenWindow) {
+		p.nextToken()
+		window, err := p.parseWindowSpec()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Window = window
+	}
+
+	stmt.Types = map[Expression]ValueType{}
+	for _, selected := range stmt.Selected {
+		if _, err := inferTypes(stmt.Types, selected); err != nil {
+			return nil, fmt.Errorf("SELECT %s: %w", selected, err)
+		}
+	}
+	if stmt.Where != nil {
+		if _, err := inferTypes(stmt.Types, stmt.Where); err != nil {
+			return nil, fmt.Errorf("WHERE %s: %w", stmt.Where, err)
+		}
+	}
+	if stmt.Having != nil {
+		if _, err := inferTypes(stmt.Types, stmt.Having); err != nil {
+			return nil, fmt.Errorf("HAVING %s: %w", stmt.Having, err)
+		}
+	}
+
+	return stmt, nil
+}
+
+// parseWindowSpec parses the argument to a WINDOW clause: either a block
+// count (`100 BLOCKS`) or a quoted wall-clock duration (`"10m"`), using the
+// same syntax as Go's time.ParseDuration.
+func (p *Parser) parseWindowSpec() (WindowSpec, error) {
+	switch p.curToken.Type {
+	case TokenInt:
+		blocks, ok := new(big.Int).SetString(p.curToken.Literal, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid block count: %q", p.curToken.Literal)
+		}
+		p.nextToken()
+		if err := p.expect(TokenBlocks, "BLOCKS"); err != nil {
+			return nil, err
+		}
+		return NewBlockWindow(blocks.Uint64()), nil
+	case TokenString:
+		duration, err := time.ParseDuration(p.curToken.Literal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window duration %q: %w", p.curToken.Literal, err)
+		}
+		p.nextToken()
+		return NewDurationWindow(duration), nil
+	default:
+		return nil, fmt.Errorf("expected a block count or duration, got %q", p.curToken.Literal)
+	}
+}
+
+func (p *Parser) parseFromClause() (*FromClause, error) {
+	switch p.curToken.Type {
+	case TokenInt:
+		address, ok := new(big.Int).SetString(p.curToken.Literal, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid contract address: %q", p.curToken.Literal)
+		}
+		p.nextToken()
+		return &FromClause{Address: address}, nil
+	case TokenAddress:
+		address, ok := new(big.Int).SetString(strings.TrimPrefix(p.curToken.Literal, "0x"), 16)
+		if !ok {
+			return nil, fmt.Errorf("invalid contract address: %q", p.curToken.Literal)
+		}
+		p.nextToken()
+		return &FromClause{Address: address}, nil
+	default:
+		return nil, fmt.Errorf("expected contract address, got %q", p.curToken.Literal)
+	}
+}
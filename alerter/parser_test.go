@@ -2,7 +2,9 @@ package alerter
 
 import (
 	"math/big"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -126,3 +128,164 @@ func TestBasicSelect(t *testing.T) {
 	expectedAddress, _ := big.NewInt(0).SetString("1234abcd", 16)
 	assert.Equal(t, expectedAddress, stmt.From.Address)
 }
+
+func TestParseComparison(t *testing.T) {
+	testCases := map[string]Expression{
+		"1 = 10":         MustNewComparisonApplication(one, ten, "="),
+		"1 != 10":        MustNewComparisonApplication(one, ten, "!="),
+		"msg.value < 10": MustNewComparisonApplication(msgValue, ten, "<"),
+		"msg.value <= 10 + 1": MustNewComparisonApplication(
+			msgValue, MustNewBinaryApplication(ten, one, "+"), "<=",
+		),
+		"1 > 10":  MustNewComparisonApplication(one, ten, ">"),
+		"1 >= 10": MustNewComparisonApplication(one, ten, ">="),
+		"1":       one,
+	}
+	for input, expected := range testCases {
+		parser, err := NewParser(NewLexer(input))
+		assert.Nil(t, err)
+		exp, err := parser.parseComparison()
+		assert.Nil(t, err)
+		assert.True(t, expected.Equals(exp), "%v != %v", expected, exp)
+	}
+}
+
+func TestParseLogical(t *testing.T) {
+	testCases := map[string]Expression{
+		"true":     NewBoolValue(true),
+		"false":    NewBoolValue(false),
+		"NOT true": MustNewLogicalApplication("not", NewBoolValue(true)),
+		"1 = 1 AND 2 = 3": MustNewLogicalApplication(
+			"and",
+			MustNewComparisonApplication(one, one, "="),
+			MustNewComparisonApplication(NewIntValue(big.NewInt(2)), NewIntValue(big.NewInt(3)), "="),
+		),
+		"1 = 1 OR 2 = 3 AND NOT false": MustNewLogicalApplication(
+			"or",
+			MustNewComparisonApplication(one, one, "="),
+			MustNewLogicalApplication(
+				"and",
+				MustNewComparisonApplication(NewIntValue(big.NewInt(2)), NewIntValue(big.NewInt(3)), "="),
+				MustNewLogicalApplication("not", NewBoolValue(false)),
+			),
+		),
+		"(1 = 1 OR 2 = 3) AND NOT false": MustNewLogicalApplication(
+			"and",
+			MustNewLogicalApplication(
+				"or",
+				MustNewComparisonApplication(one, one, "="),
+				MustNewComparisonApplication(NewIntValue(big.NewInt(2)), NewIntValue(big.NewInt(3)), "="),
+			),
+			MustNewLogicalApplication("not", NewBoolValue(false)),
+		),
+	}
+	for input, expected := range testCases {
+		parser, err := NewParser(NewLexer(input))
+		assert.Nil(t, err)
+		exp, err := parser.parseOr()
+		assert.Nil(t, err)
+		assert.True(t, expected.Equals(exp), "%v != %v", expected, exp)
+	}
+}
+
+func TestParseSelectWithWhere(t *testing.T) {
+	query := "select msg.value from 0x1234abcd where msg.value > 1 and NOT msg.sender = 0"
+	parser, err := NewParser(NewLexer(query))
+	assert.Nil(t, err)
+	stmt, err := parser.ParseSelect()
+	assert.Nil(t, err)
+	expected := MustNewLogicalApplication(
+		"and",
+		MustNewComparisonApplication(msgValue, NewIntValue(big.NewInt(1)), ">"),
+		MustNewLogicalApplication("not", MustNewComparisonApplication(NewAttribute([]string{"msg", "sender"}), NewIntValue(big.NewInt(0)), "=")),
+	)
+	assert.True(t, expected.Equals(stmt.Where), "%v != %v", expected, stmt.Where)
+}
+
+func TestParseSelectWithGroupByAndWindow(t *testing.T) {
+	query := `select msg.sender, sum(msg.value) as total from 0x1234abcd ` +
+		`group by msg.sender having total > 1 window 100 blocks`
+	parser, err := NewParser(NewLexer(query))
+	assert.Nil(t, err)
+	stmt, err := parser.ParseSelect()
+	assert.Nil(t, err)
+
+	assert.Len(t, stmt.GroupBy, 1)
+	assert.True(t, NewAttribute([]string{"msg", "sender"}).Equals(stmt.GroupBy[0]))
+
+	expectedHaving := MustNewComparisonApplication(NewAttribute([]string{"total"}), one, ">")
+	assert.True(t, expectedHaving.Equals(stmt.Having), "%v != %v", expectedHaving, stmt.Having)
+
+	expectedWindow := NewBlockWindow(100)
+	assert.Equal(t, expectedWindow, stmt.Window)
+}
+
+func TestParseSelectWithDurationWindow(t *testing.T) {
+	query := `select sum(msg.value) from 0x1234abcd window "10m"`
+	parser, err := NewParser(NewLexer(query))
+	assert.Nil(t, err)
+	stmt, err := parser.ParseSelect()
+	assert.Nil(t, err)
+	assert.Equal(t, NewDurationWindow(10*time.Minute), stmt.Window)
+}
+
+func TestParseAddressAndHashLiterals(t *testing.T) {
+	address := "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	hash := "0x" + strings.Repeat("ab", 32)
+
+	parser, err := NewParser(NewLexer(address))
+	assert.Nil(t, err)
+	exp, err := parser.parseFactor()
+	assert.Nil(t, err)
+	assert.True(t, NewAddressValue(address).Equals(exp), "%v != %v", NewAddressValue(address), exp)
+
+	parser, err = NewParser(NewLexer(hash))
+	assert.Nil(t, err)
+	exp, err = parser.parseFactor()
+	assert.Nil(t, err)
+	assert.True(t, NewHashValue(hash).Equals(exp), "%v != %v", NewHashValue(hash), exp)
+}
+
+func TestParseWhereWithAddressComparison(t *testing.T) {
+	address := "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	parser, err := NewParser(NewLexer("msg.sender = " + address))
+	assert.Nil(t, err)
+	exp, err := parser.parseOr()
+	assert.Nil(t, err)
+	expected := MustNewComparisonApplication(NewAttribute([]string{"msg", "sender"}), NewAddressValue(address), "=")
+	assert.True(t, expected.Equals(exp), "%v != %v", expected, exp)
+}
+
+func TestParseCast(t *testing.T) {
+	testCases := map[string]Expression{
+		"CAST(1 AS uint256)":         NewCastApplication(one, TypeUint256),
+		"cast(msg.value as address)": NewCastApplication(msgValue, TypeAddress),
+	}
+	for input, expected := range testCases {
+		parser, err := NewParser(NewLexer(input))
+		assert.Nil(t, err)
+		exp, err := parser.parseFactor()
+		assert.Nil(t, err)
+		assert.True(t, expected.Equals(exp), "%v != %v", expected, exp)
+	}
+}
+
+func TestParseFunctionCallOptions(t *testing.T) {
+	parser, err := NewParser(NewLexer(`SUM(msg.value, {overflow: "saturate"})`))
+	assert.Nil(t, err)
+	exp, err := parser.parseFactor()
+	assert.Nil(t, err)
+	expected := NewFunctionCallWithOptions("sum", []Expression{msgValue}, map[string]Expression{
+		"overflow": NewStringValue("saturate"),
+	})
+	assert.True(t, expected.Equals(exp), "%v != %v", expected, exp)
+
+	parser, err = NewParser(NewLexer(`COUNT(tx, {distinct: true})`))
+	assert.Nil(t, err)
+	exp, err = parser.parseFactor()
+	assert.Nil(t, err)
+	expected = NewFunctionCallWithOptions("count", []Expression{NewAttribute([]string{"tx"})}, map[string]Expression{
+		"distinct": NewBoolValue(true),
+	})
+	assert.True(t, expected.Equals(exp), "%v != %v", expected, exp)
+}
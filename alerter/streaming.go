@@ -0,0 +1,263 @@
+package alerter
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// sample is one aggregate input observed for a group at a point in time.
+type sample struct {
+	block uint64
+	at    time.Time
+	value *big.Int
+}
+
+// groupState holds the samples retained for every aggregate FunctionCall in
+// a SelectStmt, for one distinct GROUP BY key, plus the last value computed
+// for each (whether or not it was emitted) so StreamEvaluator can tell
+// whether a fresh value changed.
+type groupState struct {
+	samples map[*FunctionCall][]sample
+	last    map[*FunctionCall]*big.Int
+}
+
+// Row is a single observation fed to StreamEvaluator.Process: an evaluation
+// context (e.g. the attributes of one transaction) together with the block
+// number and timestamp it was observed at, used to evict samples that have
+// aged out of the WINDOW.
+type Row struct {
+	Context *EvalContext
+	Block   uint64
+	At      time.Time
+}
+
+// Result is one aggregated output row, keyed by the GROUP BY values that
+// produced it. Values is keyed by column alias, falling back to "col<i>"
+// for unaliased selections.
+type Result struct {
+	GroupBy []Expression
+	Values  map[string]Expression
+}
+
+// StreamEvaluator incrementally evaluates a windowed, optionally grouped
+// SelectStmt over a stream of Rows, maintaining per-group accumulator state
+// for SUM, COUNT, AVG, MIN and MAX and emitting a Result whenever a group's
+// aggregated value changes and (if present) satisfies HAVING.
+type StreamEvaluator struct {
+	stmt   *SelectStmt
+	groups map[string]*groupState
+}
+
+// NewStreamEvaluator creates a StreamEvaluator for stmt, which must carry a
+// WINDOW clause.
+func NewStreamEvaluator(stmt *SelectStmt) (*StreamEvaluator, error) {
+	if stmt.Window == nil {
+		return nil, fmt.Errorf("streaming evaluation requires a WINDOW clause")
+	}
+	return &StreamEvaluator{stmt: stmt, groups: map[string]*groupState{}}, nil
+}
+
+// Process filters row through WHERE, folds it into the state of the group it
+// belongs to, evicts samples that have aged out of the window, and returns
+// the group's new Result if its aggregated value changed and satisfies
+// HAVING (when present). It returns a nil Result when the row is filtered
+// out or nothing should be emitted.
+func (s *StreamEvaluator) Process(row Row) (*Result, error) {
+	passes, err := s.satisfiesWhere(row.Context)
+	if err != nil {
+		return nil, err
+	}
+	if !passes {
+		return nil, nil
+	}
+
+	groupValues, err := s.evaluateGroupBy(row.Context)
+	if err != nil {
+		return nil, err
+	}
+	state, ok := s.groups[groupKey(groupValues)]
+	if !ok {
+		state = &groupState{samples: map[*FunctionCall][]sample{}, last: map[*FunctionCall]*big.Int{}}
+		s.groups[groupKey(groupValues)] = state
+	}
+
+	changed := false
+	values := map[string]Expression{}
+	for i, selected := range s.stmt.Selected {
+		call, ok := selected.(*FunctionCall)
+		if !ok {
+			value, err := Evaluate(selected, row.Context)
+			if err != nil {
+				return nil, err
+			}
+			values[s.columnName(i, selected)] = value
+			continue
+		}
+
+		aggregated, didChange, err := s.processAggregate(state, call, row)
+		if err != nil {
+			return nil, err
+		}
+		changed = changed || didChange
+		values[s.columnName(i, selected)] = NewIntValue(aggregated)
+	}
+
+	if !changed {
+		return nil, nil
+	}
+	satisfied, err := s.satisfiesHaving(values)
+	if err != nil {
+		return nil, err
+	}
+	if !satisfied {
+		return nil, nil
+	}
+	return &Result{GroupBy: groupValues, Values: values}, nil
+}
+
+func (s *StreamEvaluator) processAggregate(state *groupState, call *FunctionCall, row Row) (*big.Int, bool, error) {
+	if len(call.Args) != 1 {
+		return nil, false, fmt.Errorf("%s expects exactly one argument", call.Name)
+	}
+	argValue, err := Evaluate(call.Args[0], row.Context)
+	if err != nil {
+		return nil, false, err
+	}
+	intValue, ok := argValue.(*IntValue)
+	if !ok {
+		return nil, false, fmt.Errorf("%s requires an integer-valued argument, got %s", call.Name, argValue)
+	}
+
+	retained := evictExpired(state.samples[call], s.stmt.Window, row.Block, row.At)
+	state.samples[call] = append(retained, sample{block: row.Block, at: row.At, value: intValue.Value})
+
+	aggregated, err := aggregate(call.Name, state.samples[call])
+	if err != nil {
+		return nil, false, err
+	}
+	previous, seen := state.last[call]
+	state.last[call] = aggregated
+	return aggregated, !seen || previous.Cmp(aggregated) != 0, nil
+}
+
+func (s *StreamEvaluator) satisfiesWhere(ctx *EvalContext) (bool, error) {
+	if s.stmt.Where == nil {
+		return true, nil
+	}
+	result, err := Evaluate(s.stmt.Where, ctx)
+	if err != nil {
+		return false, err
+	}
+	boolValue, ok := result.(*BoolValue)
+	if !ok {
+		return false, fmt.Errorf("WHERE must evaluate to a boolean, got %s", result)
+	}
+	return boolValue.Value, nil
+}
+
+func (s *StreamEvaluator) satisfiesHaving(values map[string]Expression) (bool, error) {
+	if s.stmt.Having == nil {
+		return true, nil
+	}
+	havingCtx := NewEvalContext()
+	for name, value := range values {
+		havingCtx.Attributes[name] = value
+	}
+	result, err := Evaluate(s.stmt.Having, havingCtx)
+	if err != nil {
+		return false, err
+	}
+	boolValue, ok := result.(*BoolValue)
+	if !ok {
+		return false, fmt.Errorf("HAVING must evaluate to a boolean, got %s", result)
+	}
+	return boolValue.Value, nil
+}
+
+func (s *StreamEvaluator) evaluateGroupBy(ctx *EvalContext) ([]Expression, error) {
+	values := make([]Expression, len(s.stmt.GroupBy))
+	for i, expr := range s.stmt.GroupBy {
+		value, err := Evaluate(expr, ctx)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// columnName resolves the output column name for stmt.Selected[index]: its
+// alias if one was declared, otherwise a positional "col<index>" name.
+func (s *StreamEvaluator) columnName(index int, expr Expression) string {
+	for alias, aliased := range s.stmt.Aliases {
+		if aliased == expr {
+			return alias
+		}
+	}
+	return fmt.Sprintf("col%d", index)
+}
+
+func groupKey(values []Expression) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = value.String()
+	}
+	return strings.Join(parts, "\x1f")
+}
+
+func evictExpired(samples []sample, window WindowSpec, currentBlock uint64, currentAt time.Time) []sample {
+	retained := samples[:0]
+	for _, s := range samples {
+		if !window.Expired(s.block, s.at, currentBlock, currentAt) {
+			retained = append(retained, s)
+		}
+	}
+	return retained
+}
+
+// aggregate computes the named aggregate function over samples, using
+// *big.Int arithmetic throughout so wei-scale sums never lose precision.
+func aggregate(function string, samples []sample) (*big.Int, error) {
+	switch function {
+	case "sum":
+		return sumSamples(samples), nil
+	case "count":
+		return big.NewInt(int64(len(samples))), nil
+	case "avg":
+		if len(samples) == 0 {
+			return big.NewInt(0), nil
+		}
+		return new(big.Int).Div(sumSamples(samples), big.NewInt(int64(len(samples)))), nil
+	case "min":
+		return extremumSample(samples, -1)
+	case "max":
+		return extremumSample(samples, 1)
+	default:
+		return nil, fmt.Errorf("unsupported aggregate function: %s", function)
+	}
+}
+
+func sumSamples(samples []sample) *big.Int {
+	sum := big.NewInt(0)
+	for _, s := range samples {
+		sum.Add(sum, s.value)
+	}
+	return sum
+}
+
+// extremumSample returns the minimum sample value when sign is negative, or
+// the maximum when positive.
+func extremumSample(samples []sample, sign int) (*big.Int, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("cannot aggregate an empty window")
+	}
+	best := samples[0].value
+	for _, s := range samples[1:] {
+		if s.value.Cmp(best)*sign > 0 {
+			best = s.value
+		}
+	}
+	return new(big.Int).Set(best), nil
+}
@@ -0,0 +1,125 @@
+package alerter
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rowWithValue(value int64, block uint64, at time.Time) Row {
+	ctx := NewEvalContext()
+	ctx.Attributes["msg.value"] = NewIntValue(big.NewInt(value))
+	return Row{Context: ctx, Block: block, At: at}
+}
+
+func TestStreamEvaluatorAggregatesWithinWindow(t *testing.T) {
+	query := "select sum(msg.value) as total from 0x1234abcd window 2 blocks"
+	parser, err := NewParser(NewLexer(query))
+	assert.Nil(t, err)
+	stmt, err := parser.ParseSelect()
+	assert.Nil(t, err)
+
+	evaluator, err := NewStreamEvaluator(stmt)
+	assert.Nil(t, err)
+
+	now := time.Unix(0, 0)
+	result, err := evaluator.Process(rowWithValue(10, 1, now))
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.True(t, NewIntValue(big.NewInt(10)).Equals(result.Values["total"]))
+
+	// Block 2 is still within the 2-block window alongside block 1.
+	result, err = evaluator.Process(rowWithValue(5, 2, now))
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.True(t, NewIntValue(big.NewInt(15)).Equals(result.Values["total"]))
+
+	// Block 3 evicts block 1's sample, leaving only blocks 2 and 3.
+	result, err = evaluator.Process(rowWithValue(5, 3, now))
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.True(t, NewIntValue(big.NewInt(10)).Equals(result.Values["total"]))
+}
+
+func TestStreamEvaluatorGroupsIndependently(t *testing.T) {
+	query := "select msg.sender, sum(msg.value) as total from 0x1234abcd group by msg.sender window 10 blocks"
+	parser, err := NewParser(NewLexer(query))
+	assert.Nil(t, err)
+	stmt, err := parser.ParseSelect()
+	assert.Nil(t, err)
+
+	evaluator, err := NewStreamEvaluator(stmt)
+	assert.Nil(t, err)
+
+	now := time.Unix(0, 0)
+	alice := rowWithValue(10, 1, now)
+	alice.Context.Attributes["msg.sender"] = NewStringValue("alice")
+	bob := rowWithValue(20, 1, now)
+	bob.Context.Attributes["msg.sender"] = NewStringValue("bob")
+
+	aliceResult, err := evaluator.Process(alice)
+	assert.Nil(t, err)
+	assert.True(t, NewIntValue(big.NewInt(10)).Equals(aliceResult.Values["total"]))
+
+	bobResult, err := evaluator.Process(bob)
+	assert.Nil(t, err)
+	assert.True(t, NewIntValue(big.NewInt(20)).Equals(bobResult.Values["total"]))
+}
+
+func TestStreamEvaluatorHavingSuppressesUnsatisfiedRows(t *testing.T) {
+	query := "select sum(msg.value) as total from 0x1234abcd having total > 100 window 10 blocks"
+	parser, err := NewParser(NewLexer(query))
+	assert.Nil(t, err)
+	stmt, err := parser.ParseSelect()
+	assert.Nil(t, err)
+
+	evaluator, err := NewStreamEvaluator(stmt)
+	assert.Nil(t, err)
+
+	now := time.Unix(0, 0)
+	result, err := evaluator.Process(rowWithValue(10, 1, now))
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+
+	result, err = evaluator.Process(rowWithValue(1000, 2, now))
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.True(t, NewIntValue(big.NewInt(1010)).Equals(result.Values["total"]))
+}
+
+func TestStreamEvaluatorAppliesWhereBeforeAggregating(t *testing.T) {
+	query := "select sum(msg.value) as total from 0x1234abcd where msg.value > 100 window 10 blocks"
+	parser, err := NewParser(NewLexer(query))
+	assert.Nil(t, err)
+	stmt, err := parser.ParseSelect()
+	assert.Nil(t, err)
+
+	evaluator, err := NewStreamEvaluator(stmt)
+	assert.Nil(t, err)
+
+	now := time.Unix(0, 0)
+
+	// Filtered out by WHERE: must not be folded into the aggregate.
+	result, err := evaluator.Process(rowWithValue(10, 1, now))
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+
+	// Passes WHERE: the aggregate must reflect only this row.
+	result, err = evaluator.Process(rowWithValue(500, 2, now))
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.True(t, NewIntValue(big.NewInt(500)).Equals(result.Values["total"]))
+}
+
+func TestNewStreamEvaluatorRequiresWindow(t *testing.T) {
+	query := "select sum(msg.value) from 0x1234abcd"
+	parser, err := NewParser(NewLexer(query))
+	assert.Nil(t, err)
+	stmt, err := parser.ParseSelect()
+	assert.Nil(t, err)
+
+	_, err = NewStreamEvaluator(stmt)
+	assert.NotNil(t, err)
+}
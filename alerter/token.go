@@ -0,0 +1,80 @@
+package alerter
+
+// TokenType identifies the lexical class of a Token produced by the Lexer.
+type TokenType int
+
+const (
+	TokenIllegal TokenType = iota
+	TokenEOF
+
+	TokenIdent
+	TokenInt
+	TokenString
+	TokenAddress
+	TokenHash
+
+	// keywords
+	TokenSelect
+	TokenFrom
+	TokenAs
+	TokenWhere
+	TokenAnd
+	TokenOr
+	TokenNot
+	TokenTrue
+	TokenFalse
+	TokenCast
+	TokenGroup
+	TokenBy
+	TokenHaving
+	TokenWindow
+	TokenBlocks
+
+	// arithmetic operators
+	TokenPlus
+	TokenMinus
+	TokenStar
+	TokenSlash
+
+	// comparison operators
+	TokenEq
+	TokenNotEq
+	TokenLt
+	TokenLte
+	TokenGt
+	TokenGte
+
+	// punctuation
+	TokenLParen
+	TokenRParen
+	TokenLBrace
+	TokenRBrace
+	TokenComma
+	TokenDot
+	TokenColon
+)
+
+// keywords maps the lowercased literal of every reserved word to its TokenType.
+var keywords = map[string]TokenType{
+	"select": TokenSelect,
+	"from":   TokenFrom,
+	"as":     TokenAs,
+	"where":  TokenWhere,
+	"and":    TokenAnd,
+	"or":     TokenOr,
+	"not":    TokenNot,
+	"true":   TokenTrue,
+	"false":  TokenFalse,
+	"cast":   TokenCast,
+	"group":  TokenGroup,
+	"by":     TokenBy,
+	"having": TokenHaving,
+	"window": TokenWindow,
+	"blocks": TokenBlocks,
+}
+
+// Token is a single lexical unit together with its literal source text.
+type Token struct {
+	Type    TokenType
+	Literal string
+}
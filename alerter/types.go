@@ -0,0 +1,211 @@
+package alerter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValueType identifies the type of an expression's value once the query
+// engine has moved past the untyped IntValue/StringValue distinction.
+type ValueType int
+
+const (
+	TypeUnknown ValueType = iota
+	TypeInt
+	TypeUint256
+	TypeAddress
+	TypeBytes
+	TypeBool
+	TypeString
+	TypeTimestamp
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case TypeInt:
+		return "int"
+	case TypeUint256:
+		return "uint256"
+	case TypeAddress:
+		return "address"
+	case TypeBytes:
+		return "bytes"
+	case TypeBool:
+		return "bool"
+	case TypeString:
+		return "string"
+	case TypeTimestamp:
+		return "timestamp"
+	default:
+		return "unknown"
+	}
+}
+
+// typeNames maps the lowercased spelling accepted in `CAST(expr AS <name>)`
+// to the ValueType it denotes.
+var typeNames = map[string]ValueType{
+	"int":       TypeInt,
+	"uint256":   TypeUint256,
+	"address":   TypeAddress,
+	"bytes":     TypeBytes,
+	"bool":      TypeBool,
+	"string":    TypeString,
+	"timestamp": TypeTimestamp,
+}
+
+// knownAttributeTypes gives the inferred type of well-known EVM environment
+// attributes. Attributes outside this table, including ABI-decoded
+// op.call.arg.* paths, are TypeUnknown until annotated with an explicit CAST.
+var knownAttributeTypes = map[string]ValueType{
+	"msg.value":        TypeUint256,
+	"msg.sender":       TypeAddress,
+	"tx.hash":          TypeBytes,
+	"tx.origin":        TypeAddress,
+	"block.timestamp":  TypeTimestamp,
+	"block.number":     TypeUint256,
+	"op.call.method":   TypeString,
+	"op.call.selector": TypeBytes,
+}
+
+// InferTypes walks expr bottom-up and returns its inferred ValueType.
+// ParseSelect already runs the same pass over SelectStmt.Selected, Where and
+// Having and records the result in SelectStmt.Types, so later stages can
+// rely on every node having a type without re-deriving it themselves.
+func InferTypes(expr Expression) (ValueType, error) {
+	return inferTypes(nil, expr)
+}
+
+// inferTypes is InferTypes' recursive implementation. When types is non-nil,
+// every node's resolved ValueType is recorded into it as the walk descends,
+// which is how ParseSelect annotates a SelectStmt's Types field in one pass.
+func inferTypes(types map[Expression]ValueType, expr Expression) (ValueType, error) {
+	t, err := inferTypesUncached(types, expr)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if types != nil {
+		types[expr] = t
+	}
+	return t, nil
+}
+
+func inferTypesUncached(types map[Expression]ValueType, expr Expression) (ValueType, error) {
+	switch e := expr.(type) {
+	case *IntValue:
+		return TypeInt, nil
+	case *StringValue:
+		return TypeString, nil
+	case *BoolValue:
+		return TypeBool, nil
+	case *AddressValue:
+		return TypeAddress, nil
+	case *HashValue:
+		return TypeBytes, nil
+	case *Attribute:
+		return knownAttributeTypes[strings.Join(e.Path, ".")], nil
+	case *UnaryApplication:
+		return inferUnaryType(types, e)
+	case *BinaryApplication:
+		return inferBinaryType(types, e)
+	case *ComparisonApplication:
+		if _, err := inferTypes(types, e.Left); err != nil {
+			return TypeUnknown, err
+		}
+		if _, err := inferTypes(types, e.Right); err != nil {
+			return TypeUnknown, err
+		}
+		return TypeBool, nil
+	case *LogicalApplication:
+		return inferLogicalType(types, e)
+	case *CastApplication:
+		if _, err := inferTypes(types, e.Operand); err != nil {
+			return TypeUnknown, err
+		}
+		return e.TargetType, nil
+	case *FunctionCall:
+		return inferFunctionCallType(types, e)
+	default:
+		return TypeUnknown, fmt.Errorf("cannot infer type of %T", expr)
+	}
+}
+
+// isNumeric reports whether t can be an operand of arithmetic. TypeUnknown is
+// accepted so that attributes without a known type (e.g. undeclared
+// op.call.arg.* paths) don't make every arithmetic expression that touches
+// them fail inference; an explicit CAST narrows them when needed.
+func isNumeric(t ValueType) bool {
+	return t == TypeInt || t == TypeUint256 || t == TypeUnknown
+}
+
+func inferUnaryType(types map[Expression]ValueType, u *UnaryApplication) (ValueType, error) {
+	operand, err := inferTypes(types, u.Operand)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if !isNumeric(operand) {
+		return TypeUnknown, fmt.Errorf("unary %s requires a numeric operand, got %s", u.Operator, operand)
+	}
+	return operand, nil
+}
+
+func inferBinaryType(types map[Expression]ValueType, b *BinaryApplication) (ValueType, error) {
+	left, err := inferTypes(types, b.Left)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	right, err := inferTypes(types, b.Right)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if !isNumeric(left) || !isNumeric(right) {
+		return TypeUnknown, fmt.Errorf("%s requires numeric operands, got %s and %s", b.Operator, left, right)
+	}
+	if left == TypeUint256 || right == TypeUint256 {
+		return TypeUint256, nil
+	}
+	return TypeInt, nil
+}
+
+func inferLogicalType(types map[Expression]ValueType, l *LogicalApplication) (ValueType, error) {
+	for _, operand := range l.Operands {
+		t, err := inferTypes(types, operand)
+		if err != nil {
+			return TypeUnknown, err
+		}
+		if t != TypeBool && t != TypeUnknown {
+			return TypeUnknown, fmt.Errorf("%s requires boolean operands, got %s", l.Operator, t)
+		}
+	}
+	return TypeBool, nil
+}
+
+func inferFunctionCallType(types map[Expression]ValueType, f *FunctionCall) (ValueType, error) {
+	for _, option := range f.Options {
+		if _, err := inferTypes(types, option); err != nil {
+			return TypeUnknown, err
+		}
+	}
+	switch f.Name {
+	case "sum", "min", "max", "avg":
+		if len(f.Args) != 1 {
+			return TypeUnknown, fmt.Errorf("%s expects exactly one argument", f.Name)
+		}
+		argType, err := inferTypes(types, f.Args[0])
+		if err != nil {
+			return TypeUnknown, err
+		}
+		if f.Name == "avg" {
+			return TypeUint256, nil
+		}
+		return argType, nil
+	case "count":
+		for _, arg := range f.Args {
+			if _, err := inferTypes(types, arg); err != nil {
+				return TypeUnknown, err
+			}
+		}
+		return TypeUint256, nil
+	default:
+		return TypeUnknown, fmt.Errorf("unknown function: %s", f.Name)
+	}
+}
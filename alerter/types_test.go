@@ -0,0 +1,61 @@
+package alerter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferTypes(t *testing.T) {
+	testCases := map[string]ValueType{
+		"1":                        TypeInt,
+		"\"hello\"":                TypeString,
+		"true":                     TypeBool,
+		"msg.value":                TypeUint256,
+		"msg.sender":               TypeAddress,
+		"msg.value + 1":            TypeUint256,
+		"msg.value > 1":            TypeBool,
+		"NOT (msg.value > 1)":      TypeBool,
+		"CAST(msg.value AS bytes)": TypeBytes,
+		"SUM(msg.value)":           TypeUint256,
+		"COUNT(tx)":                TypeUint256,
+	}
+	for input, expected := range testCases {
+		parser, err := NewParser(NewLexer(input))
+		assert.Nil(t, err)
+		exp, err := parser.parseOr()
+		assert.Nil(t, err)
+		actual, err := InferTypes(exp)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual, "input %q", input)
+	}
+}
+
+func TestInferTypesRejectsNonNumericArithmetic(t *testing.T) {
+	parser, err := NewParser(NewLexer(`"a" + 1`))
+	assert.Nil(t, err)
+	exp, err := parser.parseOr()
+	assert.Nil(t, err)
+	_, err = InferTypes(exp)
+	assert.NotNil(t, err)
+}
+
+func TestParseSelectAnnotatesNodeTypes(t *testing.T) {
+	query := "select msg.value + 1 as total from 0x1234abcd where msg.sender = 0x1234abcd having total > 0"
+	parser, err := NewParser(NewLexer(query))
+	assert.Nil(t, err)
+	stmt, err := parser.ParseSelect()
+	assert.Nil(t, err)
+
+	assert.Equal(t, TypeUint256, stmt.Types[stmt.Selected[0]])
+	assert.Equal(t, TypeBool, stmt.Types[stmt.Where])
+	assert.Equal(t, TypeBool, stmt.Types[stmt.Having])
+}
+
+func TestParseSelectRejectsIllTypedWhere(t *testing.T) {
+	query := `select 1 from 0x1234abcd where "a" + 1 > 0`
+	parser, err := NewParser(NewLexer(query))
+	assert.Nil(t, err)
+	_, err = parser.ParseSelect()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,52 @@
+package alerter
+
+import (
+	"fmt"
+	"time"
+)
+
+// WindowSpec bounds how long a group's accumulated state is retained before
+// it's evicted, either by block count (`WINDOW <n> BLOCKS`) or by wall-clock
+// duration (`WINDOW "<duration>"`).
+type WindowSpec interface {
+	// Expired reports whether a sample observed at observedBlock/observedAt
+	// has aged out of the window as of currentBlock/currentAt.
+	Expired(observedBlock uint64, observedAt time.Time, currentBlock uint64, currentAt time.Time) bool
+	String() string
+}
+
+// BlockWindow retains samples observed within the last Blocks blocks.
+type BlockWindow struct {
+	Blocks uint64
+}
+
+// NewBlockWindow creates a BlockWindow spanning blocks blocks.
+func NewBlockWindow(blocks uint64) *BlockWindow {
+	return &BlockWindow{Blocks: blocks}
+}
+
+func (w *BlockWindow) Expired(observedBlock uint64, _ time.Time, currentBlock uint64, _ time.Time) bool {
+	return currentBlock-observedBlock >= w.Blocks
+}
+
+func (w *BlockWindow) String() string {
+	return fmt.Sprintf("%d BLOCKS", w.Blocks)
+}
+
+// DurationWindow retains samples observed within the last Duration.
+type DurationWindow struct {
+	Duration time.Duration
+}
+
+// NewDurationWindow creates a DurationWindow spanning d.
+func NewDurationWindow(d time.Duration) *DurationWindow {
+	return &DurationWindow{Duration: d}
+}
+
+func (w *DurationWindow) Expired(_ uint64, observedAt time.Time, _ uint64, currentAt time.Time) bool {
+	return currentAt.Sub(observedAt) >= w.Duration
+}
+
+func (w *DurationWindow) String() string {
+	return w.Duration.String()
+}